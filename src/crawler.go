@@ -5,6 +5,7 @@ import (
 	"net/url"
 	// "packages/src/crawler"
 	"packages/src/fetcher"
+	"packages/src/useragent"
 	"time"
 )
 
@@ -14,9 +15,17 @@ const (
 	defaultpolitenessdelay time.Duration = 500 * time.Millisecond
 	defaultdepth           int           = 16
 	defaultconcurrency     int           = 8
-	defaultUserAgent       string        = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	defaultUserAgentName   string        = "Web_CrawlerBot/1.0"
+	defaultContactURL      string        = "https://github.com/Chibioi/Web_Crawler"
 )
 
+// DefaultUserAgentPolicy returns the crawler's default identification:
+// its own name and a contact URL, instead of impersonating another
+// crawler.
+func DefaultUserAgentPolicy() *useragent.Policy {
+	return useragent.NewPolicy(defaultUserAgentName, defaultContactURL)
+}
+
 type Fetcher interface {
 	Fetch(string) (time.Duration, *http.Response, error)
 }
@@ -35,5 +44,34 @@ type Crawlersettings struct {
 	fetchtimeout time.Duration
 	crawltimeout time.Duration
 	concurrency  int
+	maxDepth     int
 	parser       fetcher.Parser
+
+	// UserAgent identifies this crawler. A Fetcher implementation reads
+	// it to set the outgoing User-Agent header on every fetch.
+	UserAgent *useragent.Policy
+
+	// Cache, when set, lets a Fetcher implementation serve (and record)
+	// full responses from a persistent store instead of the network.
+	Cache fetcher.ResponseCache
+	// CacheOnly, when true, tells a Fetcher implementation to never touch
+	// the network: it must serve exclusively from Cache and return
+	// fetcher.ErrCacheNotFound on a miss. This enables reproducible
+	// re-parsing runs and offline development against a previously
+	// captured corpus.
+	CacheOnly bool
+}
+
+// NewCrawlerSettings creates a Crawlersettings with the package defaults;
+// callers can tweak the returned value's exported fields before passing
+// it to NewCrawler.
+func NewCrawlerSettings(parser fetcher.Parser) *Crawlersettings {
+	return &Crawlersettings{
+		fetchtimeout: defaultfetchtimeout,
+		crawltimeout: defaultcrawltimeout,
+		concurrency:  defaultconcurrency,
+		maxDepth:     defaultdepth,
+		parser:       parser,
+		UserAgent:    DefaultUserAgentPolicy(),
+	}
 }
@@ -3,13 +3,28 @@ package crawler
 import (
 	"math"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	// ewmaAlpha weights how quickly the observed-latency average reacts
+	// to a new sample.
+	ewmaAlpha = 0.3
+	// delayMultiplier converts the average observed latency into a target
+	// crawl delay: a host that takes 200ms to respond gets roughly 400ms
+	// between requests.
+	delayMultiplier = 2.0
+	// defaultMaxDelay caps how slow CrawlDelay is allowed to back off to,
+	// regardless of how unhealthy a host looks.
+	defaultMaxDelay = 60 * time.Second
+)
+
 type Rule struct {
 	path    string
 	allow   bool
@@ -32,18 +47,24 @@ type Crawlingrules struct {
 	baseDomain   *url.URL
 	cache        Cacheable
 	robotsGroups *Group
+	sitemaps     []*url.URL
+	userAgent    string
 	fixedDelay   time.Duration
 	lastDelay    time.Duration
+	avgLatency   time.Duration
+	maxDelay     time.Duration
 	rwMutex      sync.RWMutex
 }
 
 // NewCrawlingRules creates a new CrawlingRules struct
 func NewCrawlingRules(baseDomain *url.URL, cache Cacheable,
-	fixedDelay time.Duration) *Crawlingrules {
+	fixedDelay time.Duration, userAgent string) *Crawlingrules {
 	return &Crawlingrules{
 		baseDomain: baseDomain,
 		cache:      cache,
 		fixedDelay: fixedDelay,
+		userAgent:  userAgent,
+		maxDelay:   defaultMaxDelay,
 	}
 }
 
@@ -53,10 +74,13 @@ func (g *Group) findRule(path string) (ret *Rule) {
 	for _, r := range g.rules {
 		if r.pattern != nil {
 			if r.pattern.MatchString(path) {
-				// Consider this a match equal to the length of the pattern.
+				// Measure against the original rule text, not the compiled
+				// regexp's string form, so precedence lines up with the
+				// spec's "longest matching entry" regardless of how much a
+				// wildcard pattern expands under QuoteMeta/anchoring.
 				// From Google's spec:
 				// The order of precedence for rules with wildcards is undefined.
-				if l := len(r.pattern.String()); l > prefixLen {
+				if l := len(r.path); l > prefixLen {
 					prefixLen = l
 					ret = r
 				}
@@ -106,6 +130,15 @@ func (r *Crawlingrules) Allowed(url *url.URL) bool {
 	return subdomain(r.baseDomain, url)
 }
 
+// Sitemaps returns the Sitemap: locations declared in robots.txt, as
+// loaded by LoadRobots. It is empty until LoadRobots has run, and if the
+// site declared none.
+func (r *Crawlingrules) Sitemaps() []*url.URL {
+	r.rwMutex.RLock()
+	defer r.rwMutex.RUnlock()
+	return r.sitemaps
+}
+
 func randDelay(value int64) time.Duration {
 	if value == 0 {
 		return 0 // No delay
@@ -139,3 +172,77 @@ func (r *Crawlingrules) CrawlDelay() time.Duration {
 	) * time.Millisecond
 
 }
+
+// RecordResponse feeds the observed latency and status code of a fetch
+// back into lastDelay so CrawlDelay adapts to how the remote host is
+// actually performing. Every response folds latency into an EWMA of
+// recent fetch durations. A 429/503 response backs lastDelay off
+// multiplicatively, honoring retryAfter (parsed from a Retry-After
+// header via ParseRetryAfter) when it asks for longer than that. Any
+// other response sets lastDelay from that EWMA, letting it decay back
+// toward fixedDelay as the host looks healthy again. lastDelay never
+// exceeds maxDelay.
+func (r *Crawlingrules) RecordResponse(latency time.Duration, status int, retryAfter time.Duration) {
+	r.rwMutex.Lock()
+	defer r.rwMutex.Unlock()
+
+	if r.avgLatency == 0 {
+		r.avgLatency = latency
+	} else {
+		r.avgLatency = time.Duration(
+			ewmaAlpha*float64(latency) + (1-ewmaAlpha)*float64(r.avgLatency),
+		)
+	}
+
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		// Seed the backoff from fixedDelay, not lastDelay alone: on a cold
+		// Crawlingrules lastDelay is still its zero value, and doubling
+		// zero never leaves zero.
+		backoff := maxDuration(r.lastDelay, r.fixedDelay) * 2
+		if retryAfter > backoff {
+			backoff = retryAfter
+		}
+		r.lastDelay = minDuration(backoff, r.maxDelay)
+		return
+	}
+
+	target := time.Duration(delayMultiplier * float64(r.avgLatency))
+	r.lastDelay = clampDuration(target, r.fixedDelay, r.maxDelay)
+}
+
+// ParseRetryAfter parses the Retry-After header, which per RFC 7231 may
+// be either a number of seconds or an HTTP-date. It returns 0 if the
+// header is absent or unparsable.
+func ParseRetryAfter(h http.Header) time.Duration {
+	value := h.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func clampDuration(value, min, max time.Duration) time.Duration {
+	return minDuration(maxDuration(value, min), max)
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
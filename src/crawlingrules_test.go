@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type memCache struct {
+	seen map[string]bool
+}
+
+func newMemCache() *memCache { return &memCache{seen: map[string]bool{}} }
+
+func (c *memCache) Set(domain, u string)          { c.seen[domain+"|"+u] = true }
+func (c *memCache) Contains(domain, u string) bool { return c.seen[domain+"|"+u] }
+
+func newTestRules(t *testing.T, fixedDelay time.Duration) *Crawlingrules {
+	t.Helper()
+	base, err := url.Parse("https://example.com")
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	return NewCrawlingRules(base, newMemCache(), fixedDelay, "TestBot/1.0")
+}
+
+func TestRecordResponseBacksOffOn429(t *testing.T) {
+	r := newTestRules(t, 0)
+
+	r.RecordResponse(50*time.Millisecond, http.StatusOK, 0)
+	before := r.lastDelay
+
+	r.RecordResponse(50*time.Millisecond, http.StatusTooManyRequests, 0)
+	if r.lastDelay <= before {
+		t.Fatalf("lastDelay after 429 = %v, want it to grow past %v", r.lastDelay, before)
+	}
+
+	r.RecordResponse(50*time.Millisecond, http.StatusTooManyRequests, 0)
+	if r.lastDelay > r.maxDelay {
+		t.Fatalf("lastDelay = %v exceeds maxDelay %v", r.lastDelay, r.maxDelay)
+	}
+}
+
+func TestRecordResponseBacksOffOn429FromColdStart(t *testing.T) {
+	fixed := 200 * time.Millisecond
+	r := newTestRules(t, fixed)
+
+	r.RecordResponse(50*time.Millisecond, http.StatusTooManyRequests, 0)
+	if r.lastDelay != 2*fixed {
+		t.Fatalf("lastDelay after a 429 on a fresh Crawlingrules = %v, want 2x fixedDelay (%v)", r.lastDelay, 2*fixed)
+	}
+}
+
+func TestRecordResponseHonorsRetryAfter(t *testing.T) {
+	r := newTestRules(t, 0)
+	r.RecordResponse(10*time.Millisecond, http.StatusServiceUnavailable, 5*time.Second)
+	if r.lastDelay != 5*time.Second {
+		t.Fatalf("lastDelay = %v, want the Retry-After value of 5s", r.lastDelay)
+	}
+}
+
+func TestRecordResponseDecaysTowardFixedDelayOnHealthyResponses(t *testing.T) {
+	fixed := 100 * time.Millisecond
+	r := newTestRules(t, fixed)
+
+	// Build up a non-zero lastDelay via a slow healthy response, then back
+	// off from it, before checking that decay actually moves it back down.
+	r.RecordResponse(2*time.Second, http.StatusOK, 0)
+	r.RecordResponse(2*time.Second, http.StatusTooManyRequests, 0)
+	backedOff := r.lastDelay
+	if backedOff <= fixed {
+		t.Fatalf("backedOff = %v, want it well above fixedDelay %v to make decay observable", backedOff, fixed)
+	}
+
+	for i := 0; i < 20; i++ {
+		r.RecordResponse(10*time.Millisecond, http.StatusOK, 0)
+	}
+	if r.lastDelay >= backedOff {
+		t.Fatalf("lastDelay after sustained healthy responses = %v, want it to have decayed below %v", r.lastDelay, backedOff)
+	}
+	if r.lastDelay < fixed {
+		t.Fatalf("lastDelay = %v should never decay below fixedDelay %v", r.lastDelay, fixed)
+	}
+}
+
+func TestClampDuration(t *testing.T) {
+	cases := []struct {
+		value, min, max, want time.Duration
+	}{
+		{5, 10, 100, 10},
+		{500, 10, 100, 100},
+		{50, 10, 100, 50},
+	}
+	for _, tc := range cases {
+		if got := clampDuration(tc.value, tc.min, tc.max); got != tc.want {
+			t.Errorf("clampDuration(%v, %v, %v) = %v, want %v", tc.value, tc.min, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "120")
+	if got := ParseRetryAfter(h); got != 120*time.Second {
+		t.Errorf("ParseRetryAfter = %v, want 120s", got)
+	}
+}
+
+func TestParseRetryAfterAbsent(t *testing.T) {
+	if got := ParseRetryAfter(http.Header{}); got != 0 {
+		t.Errorf("ParseRetryAfter with no header = %v, want 0", got)
+	}
+}
@@ -0,0 +1,274 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"packages/src/sitemap"
+)
+
+// frontierItem is a URL waiting to be fetched, along with the depth it
+// was discovered at relative to the seed.
+type frontierItem struct {
+	url   *url.URL
+	depth int
+}
+
+// hostQueue serializes fetches to a single host so CrawlDelay is honored
+// between consecutive requests, without making one slow host block
+// fetches to every other host.
+type hostQueue struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until it is this host's turn, then reserves the next slot
+// delay in the future. It returns ctx.Err() if ctx is cancelled first.
+func (q *hostQueue) wait(ctx context.Context, delay time.Duration) error {
+	q.mu.Lock()
+	wait := time.Until(q.next)
+	q.next = maxTime(q.next, time.Now()).Add(delay)
+	q.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Crawler walks a site starting from a seed URL using a pool of worker
+// goroutines, honoring Crawlingrules for eligibility and politeness.
+type Crawler struct {
+	settings *Crawlersettings
+	fetcher  Linkfetcher
+	rules    *Crawlingrules
+}
+
+// NewCrawler creates a Crawler that fetches through f and, if rules is
+// non-nil, filters and paces requests through it.
+func NewCrawler(settings *Crawlersettings, f Linkfetcher, rules *Crawlingrules) *Crawler {
+	return &Crawler{settings: settings, fetcher: f, rules: rules}
+}
+
+// Run crawls starting at seed, spawning settings.concurrency workers that
+// pull from a shared frontier. It enforces settings.fetchtimeout per URL
+// and settings.maxDepth on link depth, and stops once no new URL has
+// been discovered for settings.crawltimeout. Both returned channels are
+// closed when the crawl finishes, whether that is because it ran dry,
+// hit its idle timeout, or ctx was cancelled.
+func (c *Crawler) Run(ctx context.Context, seed *url.URL) (<-chan Parsedresults, <-chan error) {
+	results := make(chan Parsedresults)
+	errs := make(chan error)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	frontier := make(chan frontierItem, c.settings.concurrency*4)
+	resetIdle := make(chan struct{}, 1)
+
+	var hostsMu sync.Mutex
+	hosts := make(map[string]*hostQueue)
+
+	enqueue := func(item frontierItem) {
+		go func() {
+			select {
+			case frontier <- item:
+				select {
+				case resetIdle <- struct{}{}:
+				default:
+				}
+			case <-ctx.Done():
+			}
+		}()
+	}
+	var workers sync.WaitGroup
+
+	enqueue(frontierItem{url: seed, depth: 0})
+	if c.rules != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			c.seedFromSitemaps(ctx, errs, enqueue)
+		}()
+	}
+
+	for i := 0; i < c.settings.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				select {
+				case item := <-frontier:
+					c.crawlOne(ctx, item, &hostsMu, hosts, results, errs, enqueue)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		idle := time.NewTimer(c.settings.crawltimeout)
+		defer idle.Stop()
+		for {
+			select {
+			case <-resetIdle:
+				if !idle.Stop() {
+					// Drain without blocking: the idle case below may
+					// already have received the fired value.
+					select {
+					case <-idle.C:
+					default:
+					}
+				}
+				idle.Reset(c.settings.crawltimeout)
+			case <-idle.C:
+				cancel()
+			case <-ctx.Done():
+				workers.Wait()
+				close(results)
+				close(errs)
+				return
+			}
+		}
+	}()
+
+	return results, errs
+}
+
+// crawlOne fetches a single frontier item, emits its links on results (or
+// an error on errs), and enqueues links within depth for further
+// crawling.
+func (c *Crawler) crawlOne(ctx context.Context, item frontierItem, hostsMu *sync.Mutex,
+	hosts map[string]*hostQueue, results chan<- Parsedresults, errs chan<- error,
+	enqueue func(frontierItem)) {
+
+	if c.rules != nil && !c.rules.Allowed(item.url) {
+		return
+	}
+
+	host := item.url.Hostname()
+	hostsMu.Lock()
+	q, ok := hosts[host]
+	if !ok {
+		q = &hostQueue{}
+		hosts[host] = q
+	}
+	hostsMu.Unlock()
+
+	var delay time.Duration
+	if c.rules != nil {
+		delay = c.rules.CrawlDelay()
+	}
+	if err := q.wait(ctx, delay); err != nil {
+		return
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, c.settings.fetchtimeout)
+	defer cancel()
+
+	type fetched struct {
+		links []*url.URL
+		err   error
+	}
+	done := make(chan fetched, 1)
+	go func() {
+		_, links, err := c.fetcher.Fetchlinks(item.url.String())
+		done <- fetched{links: links, err: err}
+	}()
+
+	var result fetched
+	select {
+	case result = <-done:
+	case <-fetchCtx.Done():
+		result = fetched{err: fetchCtx.Err()}
+	}
+
+	if result.err != nil {
+		sendErr(ctx, errs, result.err)
+		return
+	}
+
+	links := make([]string, 0, len(result.links))
+	for _, l := range result.links {
+		links = append(links, l.String())
+	}
+	sendResult(ctx, results, Parsedresults{URL: item.url.String(), Links: links})
+
+	if item.depth >= c.settings.maxDepth {
+		return
+	}
+	for _, l := range result.links {
+		enqueue(frontierItem{url: l, depth: item.depth + 1})
+	}
+}
+
+// seedFromSitemaps discovers page URLs from every sitemap the site's
+// robots.txt declared and enqueues them all, giving the crawler a seed
+// list beyond whatever the homepage links to; crawlOne filters each one
+// through Allowed when it is dequeued.
+func (c *Crawler) seedFromSitemaps(ctx context.Context, errs chan<- error, enqueue func(frontierItem)) {
+	adapter := fetcherAdapter{c.fetcher}
+	for _, sm := range c.rules.Sitemaps() {
+		entries, err := sitemap.Discover(adapter, sm, sitemap.Options{})
+		if err != nil {
+			// Discover can fail partway through a sitemap tree (a broken
+			// nested entry, a tripped depth cap) after already
+			// collecting entries from sitemaps that parsed fine; report
+			// the error but still seed whatever it did return.
+			sendErr(ctx, errs, err)
+		}
+		for _, e := range entries {
+			// Don't filter through Allowed here: crawlOne already runs
+			// every dequeued item through it, exactly like it does for
+			// links discovered mid-crawl. Allowed's cache dedup has a
+			// side effect on first check, so checking it twice here would
+			// make crawlOne's own check always see it as already visited
+			// and silently drop every sitemap-seeded URL.
+			enqueue(frontierItem{url: e.URL, depth: 0})
+		}
+	}
+}
+
+// fetcherAdapter adapts the crawler's Fetcher to sitemap.Fetcher.
+type fetcherAdapter struct {
+	f Fetcher
+}
+
+func (a fetcherAdapter) Fetch(u *url.URL) (io.ReadCloser, error) {
+	_, resp, err := a.f.Fetch(u.String())
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func sendResult(ctx context.Context, ch chan<- Parsedresults, r Parsedresults) {
+	select {
+	case ch <- r:
+	case <-ctx.Done():
+	}
+}
+
+func sendErr(ctx context.Context, ch chan<- error, err error) {
+	select {
+	case ch <- err:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,223 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type fakeLinkFetcher struct {
+	links map[string][]*url.URL
+	delay time.Duration
+
+	// bodies, when non-nil, serves Fetch from this set keyed by URL
+	// string (used for sitemap fetches); a missing key fails the fetch.
+	// When nil, Fetch always succeeds with an empty body.
+	bodies map[string][]byte
+}
+
+func (f *fakeLinkFetcher) Fetch(u string) (time.Duration, *http.Response, error) {
+	if f.bodies == nil {
+		return f.delay, &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	body, ok := f.bodies[u]
+	if !ok {
+		return f.delay, nil, fmt.Errorf("fakeLinkFetcher: no body for %s", u)
+	}
+	return f.delay, &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (f *fakeLinkFetcher) Fetchlinks(u string) (time.Duration, []*url.URL, error) {
+	return f.delay, f.links[u], nil
+}
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", raw, err)
+	}
+	return u
+}
+
+// drainResults reads both of Run's channels until they are closed,
+// failing the test on any reported error or on taking too long.
+func drainResults(t *testing.T, results <-chan Parsedresults, errs <-chan error) []Parsedresults {
+	t.Helper()
+	var got []Parsedresults
+	for results != nil || errs != nil {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			got = append(got, r)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			t.Errorf("unexpected error from crawler: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for crawler channels to close")
+		}
+	}
+	return got
+}
+
+func TestSeedFromSitemapsKeepsPartialResultsOnError(t *testing.T) {
+	seed := mustURL(t, "https://example.com/")
+	index := mustURL(t, "https://example.com/sitemapindex.xml")
+	goodPart := mustURL(t, "https://example.com/good.xml")
+	// "https://example.com/bad.xml" deliberately has no body in f.bodies,
+	// so fetching it fails.
+
+	f := &fakeLinkFetcher{
+		links: map[string][]*url.URL{seed.String(): nil},
+		bodies: map[string][]byte{
+			index.String(): []byte(`<sitemapindex>
+  <sitemap><loc>https://example.com/good.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/bad.xml</loc></sitemap>
+</sitemapindex>`),
+			goodPart.String(): []byte(`<urlset><url><loc>https://example.com/found</loc></url></urlset>`),
+		},
+	}
+
+	base, _ := url.Parse("https://example.com")
+	rules := NewCrawlingRules(base, newMemCache(), 0, "TestBot/1.0")
+	rules.sitemaps = []*url.URL{index}
+
+	settings := NewCrawlerSettings(nil)
+	settings.concurrency = 1
+	settings.crawltimeout = 100 * time.Millisecond
+	settings.fetchtimeout = time.Second
+
+	c := NewCrawler(settings, f, rules)
+	results, errs := c.Run(context.Background(), seed)
+
+	var got []Parsedresults
+	var sawErr bool
+	for results != nil || errs != nil {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			got = append(got, r)
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			sawErr = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for crawler channels to close")
+		}
+	}
+
+	if !sawErr {
+		t.Fatal("want the broken sitemap entry to surface an error")
+	}
+
+	foundSeeded := false
+	for _, r := range got {
+		if r.URL == "https://example.com/found" {
+			foundSeeded = true
+		}
+	}
+	if !foundSeeded {
+		t.Fatalf("want the URL from the sitemap that parsed fine to still be crawled despite the sibling's error, got %+v", got)
+	}
+}
+
+func TestCrawlerRespectsMaxDepth(t *testing.T) {
+	seed := mustURL(t, "https://example.com/")
+	child := mustURL(t, "https://example.com/child")
+
+	f := &fakeLinkFetcher{links: map[string][]*url.URL{
+		seed.String():  {child},
+		child.String(): {},
+	}}
+
+	settings := NewCrawlerSettings(nil)
+	settings.concurrency = 1
+	settings.maxDepth = 0
+	settings.crawltimeout = 100 * time.Millisecond
+	settings.fetchtimeout = time.Second
+
+	c := NewCrawler(settings, f, nil)
+	results, errs := c.Run(context.Background(), seed)
+
+	got := drainResults(t, results, errs)
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1 (only the seed; children beyond maxDepth must not be fetched): %+v", len(got), got)
+	}
+	if got[0].URL != seed.String() {
+		t.Errorf("result URL = %q, want %q", got[0].URL, seed.String())
+	}
+}
+
+func TestCrawlerShutsDownAfterIdleTimeout(t *testing.T) {
+	seed := mustURL(t, "https://example.com/")
+	f := &fakeLinkFetcher{links: map[string][]*url.URL{seed.String(): nil}}
+
+	settings := NewCrawlerSettings(nil)
+	settings.concurrency = 2
+	settings.maxDepth = defaultdepth
+	settings.crawltimeout = 50 * time.Millisecond
+	settings.fetchtimeout = time.Second
+
+	c := NewCrawler(settings, f, nil)
+
+	start := time.Now()
+	results, errs := c.Run(context.Background(), seed)
+	got := drainResults(t, results, errs)
+	elapsed := time.Since(start)
+
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("crawl took %v to shut down after going idle, want close to the %v crawltimeout", elapsed, settings.crawltimeout)
+	}
+}
+
+func TestCrawlerFollowsLinksWithinDepth(t *testing.T) {
+	seed := mustURL(t, "https://example.com/")
+	child := mustURL(t, "https://example.com/child")
+	grandchild := mustURL(t, "https://example.com/child/grandchild")
+
+	f := &fakeLinkFetcher{links: map[string][]*url.URL{
+		seed.String():       {child},
+		child.String():      {grandchild},
+		grandchild.String(): {},
+	}}
+
+	settings := NewCrawlerSettings(nil)
+	settings.concurrency = 2
+	settings.maxDepth = 1
+	settings.crawltimeout = 200 * time.Millisecond
+	settings.fetchtimeout = time.Second
+
+	c := NewCrawler(settings, f, nil)
+	results, errs := c.Run(context.Background(), seed)
+	got := drainResults(t, results, errs)
+
+	seen := map[string]bool{}
+	for _, r := range got {
+		seen[r.URL] = true
+	}
+	if !seen[seed.String()] || !seen[child.String()] {
+		t.Fatalf("want seed and its direct child crawled, got %+v", got)
+	}
+	if seen[grandchild.String()] {
+		t.Fatalf("grandchild is beyond maxDepth=1 and should not have been crawled: %+v", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package cache provides a disk-backed implementation of
+// fetcher.ResponseCache.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"packages/src/fetcher"
+)
+
+// DiskCache is a fetcher.ResponseCache backed by one JSON file per
+// cached response. Entries are sharded into subdirectories keyed by a
+// prefix of the URL's hash so a single directory never holds millions of
+// files.
+type DiskCache struct {
+	dir string
+}
+
+var _ fetcher.ResponseCache = (*DiskCache)(nil)
+
+// NewDiskCache creates a DiskCache rooted at dir. The directory is
+// created lazily, on the first Put.
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+type entry struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+	TTL      time.Duration
+}
+
+// Get implements fetcher.ResponseCache.
+func (c *DiskCache) Get(url string) ([]byte, int, http.Header, bool) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil, 0, nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, 0, nil, false
+	}
+	if e.TTL > 0 && time.Since(e.StoredAt) > e.TTL {
+		return nil, 0, nil, false
+	}
+	return e.Body, e.Status, e.Header, true
+}
+
+// Put implements fetcher.ResponseCache.
+func (c *DiskCache) Put(url string, body []byte, status int, header http.Header, ttl time.Duration) error {
+	data, err := json.Marshal(entry{
+		Status:   status,
+		Header:   header,
+		Body:     body,
+		StoredAt: time.Now(),
+		TTL:      ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	path := c.path(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// path returns the sharded on-disk location for url: two levels of
+// two-hex-character directories taken from the sha256 of url, keeping
+// any single directory small regardless of corpus size.
+func (c *DiskCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	hexSum := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, hexSum[:2], hexSum[2:4], hexSum+".json")
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheMiss(t *testing.T) {
+	c := NewDiskCache(t.TempDir())
+
+	if _, _, _, ok := c.Get("https://example.com/missing"); ok {
+		t.Fatal("Get on an empty cache should report a miss")
+	}
+}
+
+func TestDiskCachePutGetRoundTrip(t *testing.T) {
+	c := NewDiskCache(t.TempDir())
+
+	header := http.Header{"Content-Type": {"text/html"}}
+	body := []byte("<html>hi</html>")
+
+	if err := c.Put("https://example.com/a", body, http.StatusOK, header, 0); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	gotBody, gotStatus, gotHeader, ok := c.Get("https://example.com/a")
+	if !ok {
+		t.Fatal("Get after Put should be a hit")
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %q, want %q", gotBody, body)
+	}
+	if gotStatus != http.StatusOK {
+		t.Errorf("status = %d, want %d", gotStatus, http.StatusOK)
+	}
+	if gotHeader.Get("Content-Type") != "text/html" {
+		t.Errorf("header Content-Type = %q, want %q", gotHeader.Get("Content-Type"), "text/html")
+	}
+}
+
+func TestDiskCachePutCreatesShardedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	c := NewDiskCache(dir)
+
+	url := "https://example.com/sharded"
+	if err := c.Put(url, []byte("body"), http.StatusOK, nil, 0); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	path := c.path(url)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected cache file at %s: %v", path, err)
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		t.Fatalf("filepath.Rel: %v", err)
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	if len(segments) != 3 {
+		t.Fatalf("path %q has %d segments, want 3 (two shard dirs + file)", rel, len(segments))
+	}
+	if len(segments[0]) != 2 || len(segments[1]) != 2 {
+		t.Fatalf("shard directories %q/%q should each be 2 hex characters", segments[0], segments[1])
+	}
+}
+
+func TestDiskCacheTTLExpiry(t *testing.T) {
+	c := NewDiskCache(t.TempDir())
+
+	if err := c.Put("https://example.com/expiring", []byte("body"), http.StatusOK, nil, 10*time.Millisecond); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if _, _, _, ok := c.Get("https://example.com/expiring"); !ok {
+		t.Fatal("entry should still be fresh immediately after Put")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, _, _, ok := c.Get("https://example.com/expiring"); ok {
+		t.Fatal("entry should report a miss once its TTL has elapsed")
+	}
+}
+
+func TestDiskCacheZeroTTLNeverExpires(t *testing.T) {
+	c := NewDiskCache(t.TempDir())
+
+	if err := c.Put("https://example.com/forever", []byte("body"), http.StatusOK, nil, 0); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, _, _, ok := c.Get("https://example.com/forever"); !ok {
+		t.Fatal("a zero TTL should mean the entry never expires")
+	}
+}
@@ -1,10 +1,29 @@
 package fetcher
 
 import (
+	"errors"
 	"io"
+	"net/http"
 	"net/url"
+	"time"
 )
 
 type Parser interface {
 	Parse(string, io.Reader) ([]*url.URL, error)
 }
+
+// ErrCacheNotFound is returned by a CacheOnly fetch when no (unexpired)
+// cached response exists for the requested URL.
+var ErrCacheNotFound = errors.New("fetcher: no cached response for URL")
+
+// ResponseCache stores full HTTP responses keyed by URL with a TTL per
+// entry, so a crawl can be re-run offline against a previously captured
+// corpus instead of hitting the network.
+type ResponseCache interface {
+	// Get returns the cached response for url, or ok=false if there is no
+	// entry, or the entry has exceeded the TTL it was stored with.
+	Get(url string) (body []byte, status int, header http.Header, ok bool)
+	// Put stores a response for url that expires after ttl (0 means it
+	// never expires).
+	Put(url string, body []byte, status int, header http.Header, ttl time.Duration) error
+}
@@ -0,0 +1,263 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRedirectHops bounds how many 3xx responses LoadRobots will follow
+// when fetching robots.txt, so a misconfigured server cannot send the
+// crawler into an infinite redirect loop.
+const maxRedirectHops = 5
+
+// RobotsData is the fully parsed contents of a robots.txt file: every
+// user-agent group it declares plus any Sitemap directives found in it.
+type RobotsData struct {
+	Groups   []*Group
+	Sitemaps []*url.URL
+}
+
+// ParseRobots parses a robots.txt document and returns the Group that
+// applies to userAgent, selecting the first User-agent block whose token
+// appears in userAgent and falling back to the "*" group when present. If nothing
+// matches, a permissive empty Group is returned, per the spec's "no
+// restrictions by default" rule.
+func ParseRobots(body []byte, userAgent string) (*Group, error) {
+	data, err := parseRobotsData(body)
+	if err != nil {
+		return nil, err
+	}
+	return data.selectGroup(userAgent), nil
+}
+
+// FromStatusAndBytes builds a RobotsData according to the status-code
+// rules of the robots.txt spec: a 2xx response is parsed normally, 4xx
+// is treated as "no robots.txt present" (allow all), and anything else
+// (5xx, or a network error reported by the caller as such) is treated as
+// "disallow all" until a robots.txt can be fetched successfully.
+func FromStatusAndBytes(status int, body []byte) (*RobotsData, error) {
+	switch {
+	case status >= 200 && status < 300:
+		return parseRobotsData(body)
+	case status >= 400 && status < 500:
+		return &RobotsData{}, nil
+	default:
+		return &RobotsData{Groups: []*Group{disallowAllGroup()}}, nil
+	}
+}
+
+func disallowAllGroup() *Group {
+	return &Group{agent: "*", rules: []*Rule{{path: "/", allow: false}}}
+}
+
+// parseRobotsData parses the raw contents of a robots.txt file into its
+// constituent groups and sitemaps. A run of consecutive User-agent lines
+// shares the rules that follow it, per the spec.
+func parseRobotsData(body []byte) (*RobotsData, error) {
+	data := &RobotsData{}
+
+	var current []*Group // groups being built from the current run of User-agent lines
+	sawRule := false      // whether the current run has seen a directive other than User-agent yet
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		field, value, ok := splitDirective(line)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(field) {
+		case "user-agent":
+			if sawRule {
+				current = nil
+				sawRule = false
+			}
+			g := &Group{agent: strings.ToLower(value)}
+			current = append(current, g)
+			data.Groups = append(data.Groups, g)
+
+		case "allow", "disallow":
+			sawRule = true
+			if len(current) == 0 {
+				continue
+			}
+			r, err := newRule(value, strings.ToLower(field) == "allow")
+			if err != nil {
+				continue // malformed pattern: skip the line, not the whole file
+			}
+			if r == nil {
+				continue // empty path: nothing to add
+			}
+			for _, g := range current {
+				g.rules = append(g.rules, r)
+			}
+
+		case "crawl-delay":
+			sawRule = true
+			secs, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			d := time.Duration(secs * float64(time.Second))
+			for _, g := range current {
+				g.crawlDelay = d
+			}
+
+		case "sitemap":
+			if u, err := url.Parse(value); err == nil {
+				data.Sitemaps = append(data.Sitemaps, u)
+			}
+		}
+	}
+
+	return data, scanner.Err()
+}
+
+// selectGroup picks the Group that applies to userAgent: the first group
+// whose agent token appears in userAgent, falling back to the "*" group,
+// falling back to an empty (allow-all) Group.
+func (d *RobotsData) selectGroup(userAgent string) *Group {
+	ua := strings.ToLower(userAgent)
+
+	var wildcard *Group
+	for _, g := range d.Groups {
+		if g.agent == "*" {
+			if wildcard == nil {
+				wildcard = g
+			}
+			continue
+		}
+		if g.agent != "" && strings.Contains(ua, g.agent) {
+			return g
+		}
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return &Group{agent: "*"}
+}
+
+// splitDirective splits a "Field: value" robots.txt line.
+func splitDirective(line string) (field, value string, ok bool) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), true
+}
+
+// stripComment removes a trailing "# ..." comment from a robots.txt line.
+func stripComment(line string) string {
+	if idx := strings.Index(line, "#"); idx >= 0 {
+		return line[:idx]
+	}
+	return line
+}
+
+// newRule builds a Rule from a robots.txt path pattern. Patterns
+// containing "*" or a trailing "$" are compiled to a regexp; plain paths
+// keep using prefix matching in Group.findRule.
+func newRule(path string, allow bool) (*Rule, error) {
+	if path == "" {
+		// An empty Disallow means "allow everything"; an empty Allow is a
+		// no-op. Either way there is nothing to restrict, so no Rule.
+		return nil, nil
+	}
+	if !strings.ContainsAny(path, "*$") {
+		return &Rule{path: path, allow: allow}, nil
+	}
+	pattern, err := wildcardToRegexp(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Rule{path: path, allow: allow, pattern: pattern}, nil
+}
+
+// wildcardToRegexp translates robots.txt wildcard syntax ("*" matches
+// any sequence of characters, a trailing "$" anchors to end-of-path)
+// into a compiled regexp.
+func wildcardToRegexp(path string) (*regexp.Regexp, error) {
+	anchorEnd := strings.HasSuffix(path, "$")
+	if anchorEnd {
+		path = strings.TrimSuffix(path, "$")
+	}
+
+	parts := strings.Split(path, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchorEnd {
+		expr += "$"
+	}
+	return regexp.Compile(expr)
+}
+
+// LoadRobots fetches /robots.txt from baseDomain using f, selects the
+// Group matching the configured user agent (falling back to "*"), and
+// stores it for use by Allowed and CrawlDelay. Redirects are followed up
+// to maxRedirectHops times; non-2xx/3xx responses are handled per
+// FromStatusAndBytes.
+func (r *Crawlingrules) LoadRobots(ctx context.Context, f Fetcher) error {
+	target := *r.baseDomain
+	target.Path = "/robots.txt"
+	target.RawQuery = ""
+
+	var data *RobotsData
+	for hop := 0; ; hop++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		_, resp, err := f.Fetch(target.String())
+		if err != nil {
+			data = &RobotsData{Groups: []*Group{disallowAllGroup()}}
+			break
+		}
+
+		if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			resp.Body.Close()
+			loc := resp.Header.Get("Location")
+			next, parseErr := url.Parse(loc)
+			if loc == "" || parseErr != nil || hop >= maxRedirectHops {
+				// An unresolvable or looping redirect chain means we don't
+				// actually know what this site's robots.txt says, same as a
+				// network error or a 5xx: fail closed.
+				data = &RobotsData{Groups: []*Group{disallowAllGroup()}}
+				break
+			}
+			target = *target.ResolveReference(next)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		data, err = FromStatusAndBytes(resp.StatusCode, body)
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	r.rwMutex.Lock()
+	r.robotsGroups = data.selectGroup(r.userAgent)
+	r.sitemaps = data.Sitemaps
+	r.rwMutex.Unlock()
+	return nil
+}
@@ -0,0 +1,145 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRobotsMultiAgentGroups(t *testing.T) {
+	body := []byte(`
+User-agent: googlebot
+User-agent: bingbot
+Disallow: /private/
+Crawl-delay: 2
+
+User-agent: *
+Disallow: /
+Allow: /public/
+
+Sitemap: https://example.com/sitemap.xml
+`)
+
+	data, err := parseRobotsData(body)
+	if err != nil {
+		t.Fatalf("parseRobotsData: %v", err)
+	}
+	if len(data.Groups) != 3 {
+		t.Fatalf("got %d groups, want 3 (googlebot, bingbot, *)", len(data.Groups))
+	}
+	if len(data.Sitemaps) != 1 || data.Sitemaps[0].String() != "https://example.com/sitemap.xml" {
+		t.Fatalf("unexpected sitemaps: %+v", data.Sitemaps)
+	}
+
+	for _, name := range []string{"googlebot", "bingbot"} {
+		g, err := ParseRobots(body, name)
+		if err != nil {
+			t.Fatalf("ParseRobots(%q): %v", name, err)
+		}
+		if g.crawlDelay != 2*time.Second {
+			t.Errorf("%s: crawlDelay = %v, want 2s", name, g.crawlDelay)
+		}
+		if g.Test("/private/page") {
+			t.Errorf("%s: /private/page should be disallowed", name)
+		}
+		if !g.Test("/anything-else") {
+			t.Errorf("%s: /anything-else should be allowed (no matching rule)", name)
+		}
+	}
+
+	other, err := ParseRobots(body, "SomeOtherCrawler/1.0")
+	if err != nil {
+		t.Fatalf("ParseRobots(other): %v", err)
+	}
+	if other.Test("/blocked") {
+		t.Error("* group should disallow /blocked for an unmatched agent")
+	}
+	if !other.Test("/public/page") {
+		t.Error("* group should allow /public/page for an unmatched agent")
+	}
+}
+
+func TestParseRobotsNoMatchIsPermissive(t *testing.T) {
+	g, err := ParseRobots([]byte("User-agent: googlebot\nDisallow: /\n"), "bingbot")
+	if err != nil {
+		t.Fatalf("ParseRobots: %v", err)
+	}
+	if !g.Test("/anything") {
+		t.Error("a crawler with no matching group and no '*' group should be unrestricted")
+	}
+}
+
+func TestFromStatusAndBytes(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		path   string
+		want   bool // Test(path) result for an arbitrary agent
+	}{
+		{"2xx parses body", 200, "/disallowed", false},
+		{"4xx allows all", 404, "/disallowed", true},
+		{"5xx disallows all", 503, "/anything", false},
+		{"network-style error status disallows all", 599, "/anything", false},
+	}
+
+	body := []byte("User-agent: *\nDisallow: /disallowed\n")
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := FromStatusAndBytes(tc.status, body)
+			if err != nil {
+				t.Fatalf("FromStatusAndBytes: %v", err)
+			}
+			g := data.selectGroup("anyagent")
+			if got := g.Test(tc.path); got != tc.want {
+				t.Errorf("Test(%q) = %v, want %v", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWildcardToRegexp(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/private/*", "/private/inner/page", true},
+		{"/private/*", "/public/page", false},
+		{"/*.pdf$", "/files/report.pdf", true},
+		{"/*.pdf$", "/files/report.pdf.bak", false},
+		{"/a*b", "/axxxb", true},
+		{"/a*b", "/axxx", false},
+	}
+
+	for _, tc := range cases {
+		re, err := wildcardToRegexp(tc.pattern)
+		if err != nil {
+			t.Fatalf("wildcardToRegexp(%q): %v", tc.pattern, err)
+		}
+		if got := re.MatchString(tc.path); got != tc.match {
+			t.Errorf("pattern %q against %q = %v, want %v", tc.pattern, tc.path, got, tc.match)
+		}
+	}
+}
+
+func TestFindRuleLongestMatchUsesOriginalPathLength(t *testing.T) {
+	// "/a.b.c.d.e*" (11 chars) compiles to "^/a\.b\.c\.d\.e.*" (17 chars):
+	// escaping the literal dots inflates the compiled regexp well past
+	// the rule's own text. Precedence must be decided on the original
+	// rule text length (11 here), not the compiled pattern's length (17),
+	// or this shorter wildcard Allow would incorrectly outrank the
+	// longer, more specific, plain Disallow below (14 chars) for a path
+	// both match.
+	body := []byte("User-agent: *\nAllow: /a.b.c.d.e*\nDisallow: /a.b.c.d.e/pri\n")
+
+	g, err := ParseRobots(body, "anyagent")
+	if err != nil {
+		t.Fatalf("ParseRobots: %v", err)
+	}
+	if g.Test("/a.b.c.d.e/private") {
+		t.Error("the longer, more specific plain Disallow rule should win over the shorter wildcard Allow rule")
+	}
+	if !g.Test("/a.b.c.d.e/other") {
+		t.Error("/a.b.c.d.e/other should remain allowed by the wildcard rule")
+	}
+}
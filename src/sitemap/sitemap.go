@@ -0,0 +1,187 @@
+// Package sitemap discovers page URLs advertised through sitemap.xml and
+// sitemapindex.xml files, per https://www.sitemaps.org/protocol.html.
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMaxDepth bounds how many levels of sitemapindex nesting
+	// Discover will follow.
+	defaultMaxDepth = 5
+	// defaultMaxURLs bounds how many page URLs Discover will collect in
+	// total, across every sitemap it follows.
+	defaultMaxURLs = 50000
+	// maxSitemapBytes bounds how much of a single sitemap file Discover
+	// will read, so a hostile or corrupt file can't exhaust memory.
+	maxSitemapBytes = 50 << 20 // 50MiB
+)
+
+// Fetcher fetches the raw bytes available at u. Callers typically adapt
+// their existing HTTP client or crawler.Fetcher to this interface.
+type Fetcher interface {
+	Fetch(u *url.URL) (io.ReadCloser, error)
+}
+
+// Entry is one page URL discovered in a sitemap.
+type Entry struct {
+	URL     *url.URL
+	LastMod time.Time // zero if the sitemap didn't declare one, or declared one not in RFC 3339 form (e.g. date-only)
+}
+
+// Options bounds and filters a Discover call.
+type Options struct {
+	// MaxDepth bounds sitemapindex recursion. Zero means defaultMaxDepth.
+	MaxDepth int
+	// MaxURLs bounds the total number of entries returned. Zero means
+	// defaultMaxURLs.
+	MaxURLs int
+	// Since, if non-zero, drops entries whose lastmod predates it,
+	// letting a caller do an incremental crawl of only what changed.
+	Since time.Time
+}
+
+// Discover fetches seed and, recursively following any sitemapindex
+// entries (decompressing ".xml.gz" files along the way), returns every
+// page URL found across the sitemap tree, bounded by opts.
+func Discover(f Fetcher, seed *url.URL, opts Options) ([]Entry, error) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = defaultMaxDepth
+	}
+	if opts.MaxURLs <= 0 {
+		opts.MaxURLs = defaultMaxURLs
+	}
+
+	var entries []Entry
+	err := discover(f, seed, opts, 0, &entries)
+	return entries, err
+}
+
+func discover(f Fetcher, u *url.URL, opts Options, depth int, entries *[]Entry) error {
+	if depth > opts.MaxDepth {
+		return fmt.Errorf("sitemap: exceeded max recursion depth (%d) at %s", opts.MaxDepth, u)
+	}
+	if len(*entries) >= opts.MaxURLs {
+		return nil
+	}
+
+	data, err := fetchBody(f, u)
+	if err != nil {
+		return fmt.Errorf("sitemap: fetching %s: %w", u, err)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return fmt.Errorf("sitemap: parsing %s: %w", u, err)
+	}
+
+	switch root {
+	case "sitemapindex":
+		var idx sitemapIndex
+		if err := xml.Unmarshal(data, &idx); err != nil {
+			return fmt.Errorf("sitemap: parsing %s: %w", u, err)
+		}
+		for _, s := range idx.Sitemaps {
+			if len(*entries) >= opts.MaxURLs {
+				return nil
+			}
+			next, err := resolve(u, s.Loc)
+			if err != nil {
+				continue
+			}
+			if err := discover(f, next, opts, depth+1, entries); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default: // "urlset"; any other root fails to unmarshal and is reported as a parse error
+		var set urlSet
+		if err := xml.Unmarshal(data, &set); err != nil {
+			return fmt.Errorf("sitemap: parsing %s: %w", u, err)
+		}
+		for _, raw := range set.URLs {
+			if len(*entries) >= opts.MaxURLs {
+				return nil
+			}
+			loc, err := resolve(u, raw.Loc)
+			if err != nil {
+				continue
+			}
+			lastMod, _ := time.Parse(time.RFC3339, raw.LastMod)
+			if !opts.Since.IsZero() && !lastMod.IsZero() && lastMod.Before(opts.Since) {
+				continue
+			}
+			*entries = append(*entries, Entry{URL: loc, LastMod: lastMod})
+		}
+		return nil
+	}
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// rootElement returns the local name of data's top-level XML element,
+// used to tell a sitemapindex apart from a urlset.
+func rootElement(data []byte) (string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local, nil
+		}
+	}
+}
+
+func resolve(base *url.URL, ref string) (*url.URL, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(u), nil
+}
+
+// fetchBody fetches u and, if its path indicates gzip compression,
+// decompresses it, returning the bounded raw contents.
+func fetchBody(f Fetcher, u *url.URL) ([]byte, error) {
+	rc, err := f.Fetch(u)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var r io.Reader = rc
+	if strings.HasSuffix(u.Path, ".gz") {
+		gz, err := gzip.NewReader(rc)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return io.ReadAll(io.LimitReader(r, maxSitemapBytes))
+}
@@ -0,0 +1,160 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeFetcher struct {
+	bodies map[string][]byte
+}
+
+func (f *fakeFetcher) Fetch(u *url.URL) (io.ReadCloser, error) {
+	body, ok := f.bodies[u.String()]
+	if !ok {
+		return nil, fmt.Errorf("fakeFetcher: no body for %s", u)
+	}
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", raw, err)
+	}
+	return u
+}
+
+func TestDiscoverParsesURLSet(t *testing.T) {
+	seed := mustURL(t, "https://example.com/sitemap.xml")
+	f := &fakeFetcher{bodies: map[string][]byte{
+		seed.String(): []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc><lastmod>2026-01-01T00:00:00Z</lastmod></url>
+  <url><loc>https://example.com/b</loc></url>
+</urlset>`),
+	}}
+
+	entries, err := Discover(f, seed, Options{})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].URL.String() != "https://example.com/a" {
+		t.Errorf("entries[0].URL = %s, want https://example.com/a", entries[0].URL)
+	}
+	if entries[0].LastMod.IsZero() {
+		t.Error("entries[0].LastMod should be parsed from <lastmod>")
+	}
+	if !entries[1].LastMod.IsZero() {
+		t.Error("entries[1].LastMod should be zero: no <lastmod> present")
+	}
+}
+
+func TestDiscoverFollowsSitemapIndex(t *testing.T) {
+	index := mustURL(t, "https://example.com/sitemapindex.xml")
+	part1 := mustURL(t, "https://example.com/part1.xml")
+	part2 := mustURL(t, "https://example.com/part2.xml")
+
+	f := &fakeFetcher{bodies: map[string][]byte{
+		index.String(): []byte(`<sitemapindex>
+  <sitemap><loc>https://example.com/part1.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/part2.xml</loc></sitemap>
+</sitemapindex>`),
+		part1.String(): []byte(`<urlset><url><loc>https://example.com/p1</loc></url></urlset>`),
+		part2.String(): []byte(`<urlset><url><loc>https://example.com/p2</loc></url></urlset>`),
+	}}
+
+	entries, err := Discover(f, index, Options{})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (one from each sitemap in the index): %+v", len(entries), entries)
+	}
+}
+
+func TestDiscoverEnforcesMaxURLs(t *testing.T) {
+	seed := mustURL(t, "https://example.com/sitemap.xml")
+	var body strings.Builder
+	body.WriteString("<urlset>")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&body, "<url><loc>https://example.com/%d</loc></url>", i)
+	}
+	body.WriteString("</urlset>")
+
+	f := &fakeFetcher{bodies: map[string][]byte{seed.String(): []byte(body.String())}}
+
+	entries, err := Discover(f, seed, Options{MaxURLs: 3})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (MaxURLs cap)", len(entries))
+	}
+}
+
+func TestDiscoverEnforcesMaxDepth(t *testing.T) {
+	seed := mustURL(t, "https://example.com/level0.xml")
+	next := mustURL(t, "https://example.com/level1.xml")
+
+	f := &fakeFetcher{bodies: map[string][]byte{
+		seed.String(): []byte(`<sitemapindex><sitemap><loc>https://example.com/level1.xml</loc></sitemap></sitemapindex>`),
+		next.String(): []byte(`<sitemapindex><sitemap><loc>https://example.com/level2.xml</loc></sitemap></sitemapindex>`),
+	}}
+
+	_, err := Discover(f, seed, Options{MaxDepth: 1})
+	if err == nil {
+		t.Fatal("want an error when a sitemapindex chain exceeds MaxDepth")
+	}
+}
+
+func TestDiscoverFiltersBySince(t *testing.T) {
+	seed := mustURL(t, "https://example.com/sitemap.xml")
+	f := &fakeFetcher{bodies: map[string][]byte{
+		seed.String(): []byte(`<urlset>
+  <url><loc>https://example.com/old</loc><lastmod>2020-01-01T00:00:00Z</lastmod></url>
+  <url><loc>https://example.com/new</loc><lastmod>2026-01-01T00:00:00Z</lastmod></url>
+</urlset>`),
+	}}
+
+	entries, err := Discover(f, seed, Options{Since: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL.String() != "https://example.com/new" {
+		t.Fatalf("got %+v, want only the entry with lastmod after Since", entries)
+	}
+}
+
+func TestDiscoverDecodesGzip(t *testing.T) {
+	seed := mustURL(t, "https://example.com/sitemap.xml.gz")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(`<urlset><url><loc>https://example.com/gz</loc></url></urlset>`)); err != nil {
+		t.Fatalf("gzip.Write: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip.Close: %v", err)
+	}
+
+	f := &fakeFetcher{bodies: map[string][]byte{seed.String(): buf.Bytes()}}
+
+	entries, err := Discover(f, seed, Options{})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL.String() != "https://example.com/gz" {
+		t.Fatalf("got %+v, want the single gzip-decoded entry", entries)
+	}
+}
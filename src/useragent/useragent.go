@@ -0,0 +1,121 @@
+// Package useragent identifies this crawler to remote servers and
+// classifies inbound user-agent strings against a dataset of known
+// crawlers.
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Policy builds the outgoing User-Agent header for every fetch. A good
+// policy names the crawler and gives operators a way to reach whoever
+// runs it, so it isn't mistaken for abuse or a spoofed browser.
+type Policy struct {
+	Name    string
+	Contact string
+}
+
+// NewPolicy creates a Policy identifying the crawler as name, with
+// contact as a URL or mailto: link operators can use to get in touch.
+func NewPolicy(name, contact string) *Policy {
+	return &Policy{Name: name, Contact: contact}
+}
+
+// Header formats the User-Agent header value for this policy, e.g.
+// "Web_CrawlerBot/1.0 (+https://github.com/Chibioi/Web_Crawler)".
+func (p *Policy) Header() string {
+	if p.Contact == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (+%s)", p.Name, p.Contact)
+}
+
+//go:embed knowncrawlers.json
+var knownCrawlersJSON []byte
+
+// knownCrawler is one entry of the known-crawler dataset: a name and a
+// regexp pattern matched against an inbound User-Agent header.
+type knownCrawler struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type compiledCrawler struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// Registry classifies user-agent strings against a dataset of known
+// crawlers. The zero value is not usable; use NewRegistry.
+type Registry struct {
+	mu       sync.RWMutex
+	crawlers []compiledCrawler
+}
+
+// NewRegistry creates a Registry seeded with the embedded known-crawler
+// dataset.
+func NewRegistry() (*Registry, error) {
+	r := &Registry{}
+	if err := r.Load(knownCrawlersJSON); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load replaces the registry's dataset from JSON bytes of the form
+// `[{"name": "...", "pattern": "..."}, ...]`, so it can be refreshed at
+// runtime without restarting the process.
+func (r *Registry) Load(data []byte) error {
+	var entries []knownCrawler
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	compiled := make([]compiledCrawler, 0, len(entries))
+	for _, e := range entries {
+		pattern, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return fmt.Errorf("useragent: compiling pattern for %q: %w", e.Name, err)
+		}
+		compiled = append(compiled, compiledCrawler{name: e.Name, pattern: pattern})
+	}
+
+	r.mu.Lock()
+	r.crawlers = compiled
+	r.mu.Unlock()
+	return nil
+}
+
+// IsKnownCrawler reports whether ua matches a known crawler, returning
+// the first match's name.
+func (r *Registry) IsKnownCrawler(ua string) (name string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.crawlers {
+		if c.pattern.MatchString(ua) {
+			return c.name, true
+		}
+	}
+	return "", false
+}
+
+// MatchingCrawlers returns the names of every known crawler whose
+// pattern matches ua, since a single User-Agent string can legitimately
+// match more than one entry.
+func (r *Registry) MatchingCrawlers(ua string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for _, c := range r.crawlers {
+		if c.pattern.MatchString(ua) {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
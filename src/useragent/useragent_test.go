@@ -0,0 +1,87 @@
+package useragent
+
+import "testing"
+
+func TestPolicyHeader(t *testing.T) {
+	cases := []struct {
+		name    string
+		contact string
+		want    string
+	}{
+		{"Web_CrawlerBot/1.0", "https://github.com/Chibioi/Web_Crawler", "Web_CrawlerBot/1.0 (+https://github.com/Chibioi/Web_Crawler)"},
+		{"Web_CrawlerBot/1.0", "", "Web_CrawlerBot/1.0"},
+	}
+	for _, tc := range cases {
+		p := NewPolicy(tc.name, tc.contact)
+		if got := p.Header(); got != tc.want {
+			t.Errorf("Header() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestRegistryIsKnownCrawler(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	name, ok := r.IsKnownCrawler("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)")
+	if !ok || name != "Googlebot" {
+		t.Errorf("IsKnownCrawler(googlebot UA) = (%q, %v), want (\"Googlebot\", true)", name, ok)
+	}
+
+	if _, ok := r.IsKnownCrawler("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15) AppleWebKit/605.1.15"); ok {
+		t.Error("a regular browser UA should not match a known crawler")
+	}
+}
+
+func TestRegistryMatchingCrawlers(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	names := r.MatchingCrawlers("some-bot bingbot/2.0")
+	found := false
+	for _, n := range names {
+		if n == "Bingbot" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("MatchingCrawlers(...) = %v, want it to include Bingbot", names)
+	}
+
+	if got := r.MatchingCrawlers("nothing-matches-this"); len(got) != 0 {
+		t.Errorf("MatchingCrawlers(unmatched UA) = %v, want empty", got)
+	}
+}
+
+func TestRegistryLoadReplacesDataset(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	if err := r.Load([]byte(`[{"name": "CustomBot", "pattern": "(?i)custombot"}]`)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if _, ok := r.IsKnownCrawler("Googlebot/2.1"); ok {
+		t.Error("Load should replace the dataset, so the embedded Googlebot entry should no longer match")
+	}
+	if name, ok := r.IsKnownCrawler("CustomBot/1.0"); !ok || name != "CustomBot" {
+		t.Errorf("IsKnownCrawler(CustomBot UA) = (%q, %v), want (\"CustomBot\", true)", name, ok)
+	}
+}
+
+func TestRegistryLoadRejectsInvalidPattern(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	if err := r.Load([]byte(`[{"name": "Broken", "pattern": "("}]`)); err == nil {
+		t.Fatal("Load should reject an entry with an invalid regexp pattern")
+	}
+}